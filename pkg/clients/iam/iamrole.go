@@ -2,7 +2,11 @@ package iam
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"net/url"
+	"regexp"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
@@ -21,8 +25,16 @@ import (
 const (
 	errCheckUpToDate    = "unable to determine if external resource is up to date"
 	errPolicyJSONEscape = "malformed AssumeRolePolicyDocument JSON"
+	errGenerateRoleName = "unable to generate a name from NamePrefix"
+	errInvalidRoleName  = "generated role name contains characters outside [\\w+=,.@-]"
+
+	// maxRoleNameLength is the maximum length of an IAM role name.
+	maxRoleNameLength = 64
 )
 
+// roleNameCharacterClass matches the characters IAM allows in a role name.
+var roleNameCharacterClass = regexp.MustCompile(`^[\w+=,.@-]+$`)
+
 // RoleClient is the external client used for IAMRole Custom Resource
 type RoleClient interface {
 	GetRole(ctx context.Context, input *iam.GetRoleInput, opts ...func(*iam.Options)) (*iam.GetRoleOutput, error)
@@ -32,6 +44,24 @@ type RoleClient interface {
 	UpdateAssumeRolePolicy(ctx context.Context, input *iam.UpdateAssumeRolePolicyInput, opts ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error)
 	TagRole(ctx context.Context, input *iam.TagRoleInput, opts ...func(*iam.Options))  (*iam.TagRoleOutput, error)
 	UntagRole(ctx context.Context, input *iam.UntagRoleInput, opts ...func(*iam.Options))  (*iam.UntagRoleOutput, error)
+	AttachRolePolicy(ctx context.Context, input *iam.AttachRolePolicyInput, opts ...func(*iam.Options)) (*iam.AttachRolePolicyOutput, error)
+	DetachRolePolicy(ctx context.Context, input *iam.DetachRolePolicyInput, opts ...func(*iam.Options)) (*iam.DetachRolePolicyOutput, error)
+	ListAttachedRolePolicies(ctx context.Context, input *iam.ListAttachedRolePoliciesInput, opts ...func(*iam.Options)) (*iam.ListAttachedRolePoliciesOutput, error)
+	PutRolePolicy(ctx context.Context, input *iam.PutRolePolicyInput, opts ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error)
+	DeleteRolePolicy(ctx context.Context, input *iam.DeleteRolePolicyInput, opts ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error)
+	GetRolePolicy(ctx context.Context, input *iam.GetRolePolicyInput, opts ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error)
+	ListRolePolicies(ctx context.Context, input *iam.ListRolePoliciesInput, opts ...func(*iam.Options)) (*iam.ListRolePoliciesOutput, error)
+	// Instance profile methods. When InstanceProfile.Enabled is set, the
+	// controller must create the profile and attach this role to it before
+	// the role is considered ready, and must detach and delete the profile
+	// before deleting the role: AWS rejects DeleteRole while the role is
+	// still attached to an instance profile.
+	CreateInstanceProfile(ctx context.Context, input *iam.CreateInstanceProfileInput, opts ...func(*iam.Options)) (*iam.CreateInstanceProfileOutput, error)
+	DeleteInstanceProfile(ctx context.Context, input *iam.DeleteInstanceProfileInput, opts ...func(*iam.Options)) (*iam.DeleteInstanceProfileOutput, error)
+	GetInstanceProfile(ctx context.Context, input *iam.GetInstanceProfileInput, opts ...func(*iam.Options)) (*iam.GetInstanceProfileOutput, error)
+	AddRoleToInstanceProfile(ctx context.Context, input *iam.AddRoleToInstanceProfileInput, opts ...func(*iam.Options)) (*iam.AddRoleToInstanceProfileOutput, error)
+	RemoveRoleFromInstanceProfile(ctx context.Context, input *iam.RemoveRoleFromInstanceProfileInput, opts ...func(*iam.Options)) (*iam.RemoveRoleFromInstanceProfileOutput, error)
+	ListInstanceProfilesForRole(ctx context.Context, input *iam.ListInstanceProfilesForRoleInput, opts ...func(*iam.Options)) (*iam.ListInstanceProfilesForRoleOutput, error)
 }
 
 // NewRoleClient returns a new client using AWS credentials as JSON encoded data.
@@ -39,6 +69,38 @@ func NewRoleClient(conf aws.Config) RoleClient {
 	return iam.NewFromConfig(conf)
 }
 
+// ResolveRoleName returns the name to use for the role, mirroring
+// Terraform's name_prefix semantics: if externalName is already set it is
+// returned unchanged so reconciles stay stable, otherwise if p.NamePrefix is
+// set a name is generated by appending a random hex suffix to the prefix,
+// truncating the prefix itself (never the suffix) so the result fits IAM's
+// 64-character role name limit. The caller is responsible for persisting
+// the resolved name via meta.SetExternalName.
+func ResolveRoleName(externalName string, p *v1beta1.IAMRoleParameters) (string, error) {
+	if externalName != "" {
+		return externalName, nil
+	}
+	if p.NamePrefix == nil || aws.ToString(p.NamePrefix) == "" {
+		return "", nil
+	}
+
+	suffixBytes := make([]byte, 5)
+	if _, err := rand.Read(suffixBytes); err != nil {
+		return "", errors.Wrap(err, errGenerateRoleName)
+	}
+	suffix := hex.EncodeToString(suffixBytes)
+
+	prefix := aws.ToString(p.NamePrefix)
+	if maxPrefixLen := maxRoleNameLength - len(suffix); len(prefix) > maxPrefixLen {
+		prefix = prefix[:maxPrefixLen]
+	}
+	name := prefix + suffix
+	if !roleNameCharacterClass.MatchString(name) {
+		return "", errors.New(errInvalidRoleName)
+	}
+	return name, nil
+}
+
 // GenerateCreateRoleInput from IAMRoleSpec
 func GenerateCreateRoleInput(name string, p *v1beta1.IAMRoleParameters) *iam.CreateRoleInput {
 	m := &iam.CreateRoleInput{
@@ -71,6 +133,90 @@ func GenerateRoleObservation(role iamtypes.Role) v1beta1.IAMRoleExternalStatus {
 	}
 }
 
+// InstanceProfileName resolves the name to use for a role's instance
+// profile, defaulting to the role name itself when the user hasn't set one.
+// p may be nil if the role has no InstanceProfile section configured.
+func InstanceProfileName(roleName string, p *v1beta1.InstanceProfileParameters) string {
+	if p != nil && p.Name != nil && aws.ToString(p.Name) != "" {
+		return aws.ToString(p.Name)
+	}
+	return roleName
+}
+
+// GenerateCreateInstanceProfileInput from an IAMRole's InstanceProfile spec.
+// p may be nil if the role has no InstanceProfile section configured.
+func GenerateCreateInstanceProfileInput(name string, p *v1beta1.InstanceProfileParameters) *iam.CreateInstanceProfileInput {
+	m := &iam.CreateInstanceProfileInput{
+		InstanceProfileName: aws.String(name),
+	}
+	if p == nil {
+		return m
+	}
+	m.Path = p.Path
+
+	if len(p.Tags) != 0 {
+		m.Tags = make([]iamtypes.Tag, len(p.Tags))
+		for i := range p.Tags {
+			m.Tags[i] = iamtypes.Tag{
+				Key:   &p.Tags[i].Key,
+				Value: &p.Tags[i].Value,
+			}
+		}
+	}
+
+	return m
+}
+
+// GenerateInstanceProfileObservation surfaces a role's instance profile ARN
+// onto IAMRoleExternalStatus so that other resources (e.g. EC2, EKS) can
+// reference it without requiring a separate IAMInstanceProfile CR.
+func GenerateInstanceProfileObservation(status *v1beta1.IAMRoleExternalStatus, profile iamtypes.InstanceProfile) {
+	status.InstanceProfileARN = aws.ToString(profile.Arn)
+}
+
+// InstanceProfileDiff is the set of actions needed to reconcile a role's
+// instance profile with its desired InstanceProfileParameters. Detach must
+// always be actioned before Delete: AWS rejects DeleteRole (and
+// DeleteInstanceProfile) while the role is still attached to the profile, so
+// the controller must RemoveRoleFromInstanceProfile before it can
+// DeleteInstanceProfile or DeleteRole.
+type InstanceProfileDiff struct {
+	// Create is true when p is enabled but no instance profile has been
+	// observed for this role yet.
+	Create bool
+	// Attach is true when p is enabled but this role hasn't been observed
+	// attached to any instance profile; it is actioned once Create (if set)
+	// has completed.
+	Attach bool
+	// Detach is true when this role is attached to an instance profile that
+	// is no longer desired (p is nil/disabled, or the role is being
+	// deleted) and must be removed from it.
+	Detach bool
+	// Delete is true when the instance profile itself is no longer desired
+	// and should be deleted, once Detach (if set) has completed.
+	Delete bool
+}
+
+// DiffInstanceProfile determines what create/attach/detach/delete actions
+// are needed to bring a role's instance profile in line with p. observed is
+// the instance profile currently attached to the role as seen by
+// ListInstanceProfilesForRole, or nil if none is attached. Pass p as nil (or
+// with Enabled false) to tear down an observed profile, e.g. when the role
+// itself is being deleted.
+func DiffInstanceProfile(p *v1beta1.InstanceProfileParameters, observed *iamtypes.InstanceProfile) InstanceProfileDiff {
+	enabled := p != nil && p.Enabled
+	if !enabled {
+		if observed == nil {
+			return InstanceProfileDiff{}
+		}
+		return InstanceProfileDiff{Detach: true, Delete: true}
+	}
+	if observed == nil {
+		return InstanceProfileDiff{Create: true, Attach: true}
+	}
+	return InstanceProfileDiff{}
+}
+
 // GenerateIAMRole assigns the in IAMRoleParamters to role.
 func GenerateIAMRole(in v1beta1.IAMRoleParameters, role *iamtypes.Role) error {
 
@@ -120,6 +266,26 @@ func LateInitializeRole(in *v1beta1.IAMRoleParameters, role *iamtypes.Role) {
 	}
 }
 
+// LateInitializeRoleManagedPolicies fills ManagedPolicyArns from the managed
+// policies observed to be attached to an imported role.
+func LateInitializeRoleManagedPolicies(in *v1beta1.IAMRoleParameters, attached []iamtypes.AttachedPolicy) {
+	if len(in.ManagedPolicyArns) != 0 || len(attached) == 0 {
+		return
+	}
+	for _, p := range attached {
+		in.ManagedPolicyArns = append(in.ManagedPolicyArns, aws.ToString(p.PolicyArn))
+	}
+}
+
+// LateInitializeRoleInlinePolicies fills InlinePolicies from the inline
+// policy documents observed on an imported role, keyed by policy name.
+func LateInitializeRoleInlinePolicies(in *v1beta1.IAMRoleParameters, observed map[string]string) {
+	if in.InlinePolicies != nil || len(observed) == 0 {
+		return
+	}
+	in.InlinePolicies = observed
+}
+
 // CreatePatch creates a *v1beta1.IAMRoleParameters that has only the changed
 // values between the target *v1beta1.IAMRoleParameters and the current
 // *iamtypes.Role
@@ -138,8 +304,22 @@ func CreatePatch(in *iamtypes.Role, target *v1beta1.IAMRoleParameters) (*v1beta1
 	return patch, nil
 }
 
-// IsRoleUpToDate checks whether there is a change in any of the modifiable fields in role.
-func IsRoleUpToDate(in v1beta1.IAMRoleParameters, observed iamtypes.Role) (bool, error) {
+// IsRoleUpToDate checks whether there is a change in any of the modifiable
+// fields in role, in the managed policies attached to it, or in its inline
+// policies.
+func IsRoleUpToDate(in v1beta1.IAMRoleParameters, observed iamtypes.Role, attachedPolicies []iamtypes.AttachedPolicy, inlinePolicies map[string]string) (bool, error) {
+	if attach, detach := DiffIAMRolePolicies(in.ManagedPolicyArns, attachedPolicies); len(attach) != 0 || len(detach) != 0 {
+		return false, nil
+	}
+
+	inlineDiff, err := DiffIAMRoleInlinePolicies(in.InlinePolicies, inlinePolicies)
+	if err != nil {
+		return false, err
+	}
+	if len(inlineDiff.Put) != 0 || len(inlineDiff.Delete) != 0 {
+		return false, nil
+	}
+
 	generated, err := copystructure.Copy(&observed)
 	if err != nil {
 		return true, errors.Wrap(err, errCheckUpToDate)
@@ -153,6 +333,26 @@ func IsRoleUpToDate(in v1beta1.IAMRoleParameters, observed iamtypes.Role) (bool,
 		return false, err
 	}
 
+	if in.AssumeRolePolicyDocument != "" {
+		// The observed document is URL-encoded, the same form that
+		// CompactAndEscapeJSON produces for the desired one.
+		observedDocument, err := url.QueryUnescape(aws.ToString(observed.AssumeRolePolicyDocument))
+		if err != nil {
+			return false, errors.Wrap(err, errPolicyJSONEscape)
+		}
+		equivalent, err := ArePoliciesEquivalent(in.AssumeRolePolicyDocument, observedDocument)
+		if err != nil {
+			return false, err
+		}
+		if !equivalent {
+			return false, nil
+		}
+		// The documents are semantically equivalent but may differ in
+		// whitespace or key ordering; align them so the field-by-field
+		// comparison below doesn't flag that as drift.
+		desired.AssumeRolePolicyDocument = observed.AssumeRolePolicyDocument
+	}
+
 	return cmp.Equal(desired, &observed, cmpopts.IgnoreInterfaces(struct{ resource.AttributeReferencer }{})), nil
 }
 
@@ -179,3 +379,66 @@ func DiffIAMTags(local []v1beta1.Tag, remote []iam.Tag) (add []iam.Tag, remove [
 	}
 	return add, remove
 }
+
+// DiffIAMRolePolicies returns the managed policy ARNs that need to be
+// attached and detached to reconcile the observed attachments on a role
+// with the desired set.
+func DiffIAMRolePolicies(desired []string, observed []iamtypes.AttachedPolicy) (attach []string, detach []string) {
+	desiredSet := make(map[string]struct{}, len(desired))
+	for _, arn := range desired {
+		desiredSet[arn] = struct{}{}
+	}
+	observedSet := make(map[string]struct{}, len(observed))
+	for _, p := range observed {
+		observedSet[aws.ToString(p.PolicyArn)] = struct{}{}
+	}
+	for arn := range desiredSet {
+		if _, ok := observedSet[arn]; !ok {
+			attach = append(attach, arn)
+		}
+	}
+	for arn := range observedSet {
+		if _, ok := desiredSet[arn]; !ok {
+			detach = append(detach, arn)
+		}
+	}
+	return attach, detach
+}
+
+// InlinePolicyDiff is the set of PutRolePolicy/DeleteRolePolicy calls needed
+// to reconcile a role's observed inline policies with the desired ones.
+type InlinePolicyDiff struct {
+	// Put maps policy name to document, for policies that need to be
+	// created or updated.
+	Put map[string]string
+	// Delete lists the names of policies that are no longer desired.
+	Delete []string
+}
+
+// DiffIAMRoleInlinePolicies computes the InlinePolicyDiff between the
+// desired and observed inline policies of a role, using semantic policy
+// equivalence so that whitespace or ordering differences alone don't result
+// in an update.
+func DiffIAMRoleInlinePolicies(desired, observed map[string]string) (*InlinePolicyDiff, error) {
+	diff := &InlinePolicyDiff{Put: make(map[string]string)}
+	for name, doc := range desired {
+		current, ok := observed[name]
+		if !ok {
+			diff.Put[name] = doc
+			continue
+		}
+		equivalent, err := ArePoliciesEquivalent(doc, current)
+		if err != nil {
+			return nil, err
+		}
+		if !equivalent {
+			diff.Put[name] = doc
+		}
+	}
+	for name := range observed {
+		if _, ok := desired[name]; !ok {
+			diff.Delete = append(diff.Delete, name)
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,82 @@
+package iam
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDiffIAMRoleInlinePolicies(t *testing.T) {
+	const readOnly = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	const readOnlyReordered = `{"Statement":[{"Resource":"*","Effect":"Allow","Action":"s3:GetObject"}],"Version":"2012-10-17"}`
+	const readWrite = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","s3:PutObject"],"Resource":"*"}]}`
+
+	type args struct {
+		desired  map[string]string
+		observed map[string]string
+	}
+	cases := map[string]struct {
+		args    args
+		want    *InlinePolicyDiff
+		wantErr bool
+	}{
+		"NoChange": {
+			args: args{
+				desired:  map[string]string{"read-only": readOnly},
+				observed: map[string]string{"read-only": readOnly},
+			},
+			want: &InlinePolicyDiff{Put: map[string]string{}},
+		},
+		"EquivalentButReordered": {
+			args: args{
+				desired:  map[string]string{"read-only": readOnly},
+				observed: map[string]string{"read-only": readOnlyReordered},
+			},
+			want: &InlinePolicyDiff{Put: map[string]string{}},
+		},
+		"NewPolicy": {
+			args: args{
+				desired:  map[string]string{"read-only": readOnly},
+				observed: map[string]string{},
+			},
+			want: &InlinePolicyDiff{Put: map[string]string{"read-only": readOnly}},
+		},
+		"ChangedPolicy": {
+			args: args{
+				desired:  map[string]string{"access": readWrite},
+				observed: map[string]string{"access": readOnly},
+			},
+			want: &InlinePolicyDiff{Put: map[string]string{"access": readWrite}},
+		},
+		"RemovedPolicy": {
+			args: args{
+				desired:  map[string]string{},
+				observed: map[string]string{"read-only": readOnly},
+			},
+			want: &InlinePolicyDiff{Put: map[string]string{}, Delete: []string{"read-only"}},
+		},
+		"MalformedObservedDocument": {
+			args: args{
+				desired:  map[string]string{"read-only": readOnly},
+				observed: map[string]string{"read-only": "not json"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := DiffIAMRoleInlinePolicies(tc.args.desired, tc.args.observed)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("DiffIAMRoleInlinePolicies(...): got err = %v, wantErr = %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("DiffIAMRoleInlinePolicies(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
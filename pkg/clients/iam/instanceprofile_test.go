@@ -0,0 +1,137 @@
+package iam
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/provider-aws/apis/identity/v1beta1"
+)
+
+func TestInstanceProfileName(t *testing.T) {
+	cases := map[string]struct {
+		roleName string
+		p        *v1beta1.InstanceProfileParameters
+		want     string
+	}{
+		"NilParametersDefaultsToRoleName": {
+			roleName: "my-role",
+			p:        nil,
+			want:     "my-role",
+		},
+		"NoNameSetDefaultsToRoleName": {
+			roleName: "my-role",
+			p:        &v1beta1.InstanceProfileParameters{},
+			want:     "my-role",
+		},
+		"ExplicitNameOverridesRoleName": {
+			roleName: "my-role",
+			p:        &v1beta1.InstanceProfileParameters{Name: aws.String("my-profile")},
+			want:     "my-profile",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := InstanceProfileName(tc.roleName, tc.p)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("InstanceProfileName(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateCreateInstanceProfileInput(t *testing.T) {
+	cases := map[string]struct {
+		name string
+		p    *v1beta1.InstanceProfileParameters
+		want *iam.CreateInstanceProfileInput
+	}{
+		"NilParameters": {
+			name: "my-profile",
+			p:    nil,
+			want: &iam.CreateInstanceProfileInput{InstanceProfileName: aws.String("my-profile")},
+		},
+		"PathPassthrough": {
+			name: "my-profile",
+			p:    &v1beta1.InstanceProfileParameters{Path: aws.String("/custom/")},
+			want: &iam.CreateInstanceProfileInput{
+				InstanceProfileName: aws.String("my-profile"),
+				Path:                aws.String("/custom/"),
+			},
+		},
+		"TagsPassthrough": {
+			name: "my-profile",
+			p: &v1beta1.InstanceProfileParameters{
+				Tags: []v1beta1.Tag{{Key: "env", Value: "prod"}},
+			},
+			want: &iam.CreateInstanceProfileInput{
+				InstanceProfileName: aws.String("my-profile"),
+				Tags: []iamtypes.Tag{
+					{Key: aws.String("env"), Value: aws.String("prod")},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := GenerateCreateInstanceProfileInput(tc.name, tc.p)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("GenerateCreateInstanceProfileInput(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiffInstanceProfile(t *testing.T) {
+	enabled := &v1beta1.InstanceProfileParameters{Enabled: true}
+	disabled := &v1beta1.InstanceProfileParameters{Enabled: false}
+	observed := &iamtypes.InstanceProfile{InstanceProfileName: aws.String("my-role")}
+
+	cases := map[string]struct {
+		p        *v1beta1.InstanceProfileParameters
+		observed *iamtypes.InstanceProfile
+		want     InstanceProfileDiff
+	}{
+		"EnabledNotYetCreated": {
+			p:    enabled,
+			want: InstanceProfileDiff{Create: true, Attach: true},
+		},
+		"EnabledAndAlreadyAttached": {
+			p:        enabled,
+			observed: observed,
+			want:     InstanceProfileDiff{},
+		},
+		"NilParametersWithObservedProfile": {
+			p:        nil,
+			observed: observed,
+			want:     InstanceProfileDiff{Detach: true, Delete: true},
+		},
+		"DisabledWithObservedProfile": {
+			p:        disabled,
+			observed: observed,
+			want:     InstanceProfileDiff{Detach: true, Delete: true},
+		},
+		"NilParametersNoObservedProfile": {
+			p:    nil,
+			want: InstanceProfileDiff{},
+		},
+		"DisabledNoObservedProfile": {
+			p:    disabled,
+			want: InstanceProfileDiff{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := DiffInstanceProfile(tc.p, tc.observed)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("DiffInstanceProfile(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package iam
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffIAMRolePolicies(t *testing.T) {
+	type args struct {
+		desired  []string
+		observed []iamtypes.AttachedPolicy
+	}
+	type want struct {
+		attach []string
+		detach []string
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"NoChange": {
+			args: args{
+				desired: []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"},
+				observed: []iamtypes.AttachedPolicy{
+					{PolicyArn: aws.String("arn:aws:iam::aws:policy/ReadOnlyAccess")},
+				},
+			},
+			want: want{},
+		},
+		"AttachNew": {
+			args: args{
+				desired:  []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"},
+				observed: nil,
+			},
+			want: want{attach: []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"}},
+		},
+		"DetachRemoved": {
+			args: args{
+				desired: nil,
+				observed: []iamtypes.AttachedPolicy{
+					{PolicyArn: aws.String("arn:aws:iam::aws:policy/ReadOnlyAccess")},
+				},
+			},
+			want: want{detach: []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"}},
+		},
+		"AttachAndDetach": {
+			args: args{
+				desired: []string{"arn:aws:iam::aws:policy/PowerUserAccess"},
+				observed: []iamtypes.AttachedPolicy{
+					{PolicyArn: aws.String("arn:aws:iam::aws:policy/ReadOnlyAccess")},
+				},
+			},
+			want: want{
+				attach: []string{"arn:aws:iam::aws:policy/PowerUserAccess"},
+				detach: []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			attach, detach := DiffIAMRolePolicies(tc.args.desired, tc.args.observed)
+			sort.Strings(attach)
+			sort.Strings(detach)
+			if diff := cmp.Diff(tc.want.attach, attach); diff != "" {
+				t.Errorf("DiffIAMRolePolicies(...): -want attach, +got attach:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.detach, detach); diff != "" {
+				t.Errorf("DiffIAMRolePolicies(...): -want detach, +got detach:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package iam
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const errParsePolicyDocument = "unable to parse policy document"
+
+// stringSet is an IAM policy value that AWS may encode as either a single
+// string or an array of strings (e.g. Action, Resource). It always
+// unmarshals into a sorted slice so documents that differ only in whether a
+// single-element array was collapsed to a string compare equal.
+type stringSet []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *stringSet) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single == "" {
+			*s = nil
+			return nil
+		}
+		*s = stringSet{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+	sort.Strings(multi)
+	*s = multi
+	return nil
+}
+
+// principal mirrors the shapes AWS accepts for a statement's Principal or
+// NotPrincipal: the bare string "*", or an object keyed by AWS, Service,
+// Federated and/or CanonicalUser whose values may themselves be a string or
+// an array of strings.
+type principal struct {
+	Wildcard      bool
+	AWS           stringSet `json:"AWS,omitempty"`
+	Service       stringSet `json:"Service,omitempty"`
+	Federated     stringSet `json:"Federated,omitempty"`
+	CanonicalUser stringSet `json:"CanonicalUser,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *principal) UnmarshalJSON(data []byte) error {
+	var wildcard string
+	if err := json.Unmarshal(data, &wildcard); err == nil {
+		*p = principal{Wildcard: wildcard == "*"}
+		return nil
+	}
+	type alias principal
+	var a alias
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	*p = principal(a)
+	return nil
+}
+
+// policyStatement is the canonical, comparable form of a single IAM policy
+// statement. All key ordering and string-vs-slice ambiguity AWS introduces
+// server-side is normalized away at unmarshal time.
+type policyStatement struct {
+	Sid          string                          `json:"Sid,omitempty"`
+	Effect       string                          `json:"Effect"`
+	Principal    *principal                      `json:"Principal,omitempty"`
+	NotPrincipal *principal                      `json:"NotPrincipal,omitempty"`
+	Action       stringSet                       `json:"Action,omitempty"`
+	NotAction    stringSet                       `json:"NotAction,omitempty"`
+	Resource     stringSet                       `json:"Resource,omitempty"`
+	NotResource  stringSet                       `json:"NotResource,omitempty"`
+	Condition    map[string]map[string]stringSet `json:"Condition,omitempty"`
+}
+
+// policyDocument is the canonical, comparable form of an IAM policy
+// document produced by canonicalizePolicy.
+type policyDocument struct {
+	Version   string            `json:"Version,omitempty"`
+	ID        string            `json:"Id,omitempty"`
+	Statement []policyStatement `json:"Statement,omitempty"`
+}
+
+// canonicalizePolicy parses an IAM policy document JSON string into a form
+// that is safe to compare with reflect.DeepEqual: statement keys no longer
+// matter because they're unmarshaled into named fields, Principal/
+// NotPrincipal string shorthand is expanded, Action/Resource/Condition
+// values are sorted, and Effect case is normalized.
+func canonicalizePolicy(document string) (*policyDocument, error) {
+	doc := &policyDocument{}
+	if err := json.Unmarshal([]byte(document), doc); err != nil {
+		return nil, errors.Wrap(err, errParsePolicyDocument)
+	}
+	for i := range doc.Statement {
+		switch {
+		case strings.EqualFold(doc.Statement[i].Effect, "Allow"):
+			doc.Statement[i].Effect = "Allow"
+		case strings.EqualFold(doc.Statement[i].Effect, "Deny"):
+			doc.Statement[i].Effect = "Deny"
+		}
+	}
+	return doc, nil
+}
+
+// ArePoliciesEquivalent reports whether two IAM policy documents are
+// semantically equivalent, ignoring transformations AWS applies server-side
+// (statement key reordering, Principal string/slice normalization, Effect
+// casing, and single-element array collapsing) that would otherwise show up
+// as spurious drift in a byte-level comparison.
+//
+// This helper is deliberately exported from the shared iam package rather
+// than kept private to the role controller so that the IAMPolicy and
+// IAMUserPolicy controllers can wire it into their own IsUpToDate checks for
+// the same reason. Neither of those controllers exists in this checkout, so
+// that wiring isn't included here; whoever adds them should call this
+// instead of reintroducing byte-level comparison.
+func ArePoliciesEquivalent(a, b string) (bool, error) {
+	canonicalA, err := canonicalizePolicy(a)
+	if err != nil {
+		return false, err
+	}
+	canonicalB, err := canonicalizePolicy(b)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(canonicalA, canonicalB), nil
+}
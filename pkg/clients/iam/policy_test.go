@@ -0,0 +1,91 @@
+package iam
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestArePoliciesEquivalent(t *testing.T) {
+	type args struct {
+		a string
+		b string
+	}
+	type want struct {
+		equivalent bool
+		wantErr    bool
+	}
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"IdenticalDocuments": {
+			args: args{
+				a: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"ec2.amazonaws.com"}}]}`,
+				b: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"ec2.amazonaws.com"}}]}`,
+			},
+			want: want{equivalent: true},
+		},
+		"DifferentWhitespace": {
+			args: args{
+				a: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"ec2.amazonaws.com"}}]}`,
+				b: "{\n  \"Version\": \"2012-10-17\",\n  \"Statement\": [\n    {\n      \"Effect\": \"Allow\",\n      \"Action\": \"sts:AssumeRole\",\n      \"Principal\": {\"Service\": \"ec2.amazonaws.com\"}\n    }\n  ]\n}",
+			},
+			want: want{equivalent: true},
+		},
+		"StatementKeyReordering": {
+			args: args{
+				a: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+				b: `{"Version":"2012-10-17","Statement":[{"Action":"sts:AssumeRole","Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"}}]}`,
+			},
+			want: want{equivalent: true},
+		},
+		"SingleElementArrayCollapsedToString": {
+			args: args{
+				a: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["sts:AssumeRole"],"Principal":{"AWS":["arn:aws:iam::111122223333:root"]}}]}`,
+				b: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":"arn:aws:iam::111122223333:root"}}]}`,
+			},
+			want: want{equivalent: true},
+		},
+		"ActionOrderingNormalized": {
+			args: args{
+				a: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","s3:PutObject"],"Resource":"*"}]}`,
+				b: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:PutObject","s3:GetObject"],"Resource":"*"}]}`,
+			},
+			want: want{equivalent: true},
+		},
+		"EffectCaseNormalized": {
+			args: args{
+				a: `{"Version":"2012-10-17","Statement":[{"Effect":"allow","Action":"sts:AssumeRole","Resource":"*"}]}`,
+				b: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Resource":"*"}]}`,
+			},
+			want: want{equivalent: true},
+		},
+		"DifferentResource": {
+			args: args{
+				a: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::bucket-a/*"}]}`,
+				b: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"arn:aws:s3:::bucket-b/*"}]}`,
+			},
+			want: want{equivalent: false},
+		},
+		"MalformedDocument": {
+			args: args{
+				a: `not json`,
+				b: `{"Version":"2012-10-17","Statement":[]}`,
+			},
+			want: want{wantErr: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ArePoliciesEquivalent(tc.args.a, tc.args.b)
+			if (err != nil) != tc.want.wantErr {
+				t.Errorf("ArePoliciesEquivalent(...): got err = %v, wantErr = %t", err, tc.want.wantErr)
+			}
+			if diff := cmp.Diff(tc.want.equivalent, got); diff != "" {
+				t.Errorf("ArePoliciesEquivalent(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
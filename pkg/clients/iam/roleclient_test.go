@@ -0,0 +1,80 @@
+package iam
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/crossplane/provider-aws/apis/identity/v1beta1"
+)
+
+func TestResolveRoleName(t *testing.T) {
+	type args struct {
+		externalName string
+		namePrefix   *string
+	}
+	cases := map[string]struct {
+		args    args
+		want    string
+		wantErr bool
+	}{
+		"ExternalNameAlreadySet": {
+			args: args{
+				externalName: "my-role",
+				namePrefix:   aws.String("my-role-"),
+			},
+			want: "my-role",
+		},
+		"NoExternalNameNoPrefix": {
+			args: args{},
+			want: "",
+		},
+		"GeneratesNameFromPrefix": {
+			args: args{namePrefix: aws.String("my-role-")},
+		},
+		"TruncatesOverlongPrefix": {
+			args: args{namePrefix: aws.String(strings.Repeat("a", 100))},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &v1beta1.IAMRoleParameters{NamePrefix: tc.args.namePrefix}
+			got, err := ResolveRoleName(tc.args.externalName, p)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ResolveRoleName(...): got err = %v, wantErr = %t", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if tc.want != "" {
+				if got != tc.want {
+					t.Errorf("ResolveRoleName(...): got %q, want %q", got, tc.want)
+				}
+				return
+			}
+			if tc.args.namePrefix == nil {
+				if got != "" {
+					t.Errorf("ResolveRoleName(...): got %q, want empty string", got)
+				}
+				return
+			}
+			if len(got) > maxRoleNameLength {
+				t.Errorf("ResolveRoleName(...): got name of length %d, want <= %d", len(got), maxRoleNameLength)
+			}
+			if !roleNameCharacterClass.MatchString(got) {
+				t.Errorf("ResolveRoleName(...): got %q, contains characters outside [\\w+=,.@-]", got)
+			}
+			prefix := aws.ToString(tc.args.namePrefix)
+			maxPrefixLen := maxRoleNameLength - 10 // 5 bytes -> 10 hex chars
+			wantPrefix := prefix
+			if len(wantPrefix) > maxPrefixLen {
+				wantPrefix = wantPrefix[:maxPrefixLen]
+			}
+			if !strings.HasPrefix(got, wantPrefix) {
+				t.Errorf("ResolveRoleName(...): got %q, want prefix %q", got, wantPrefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package iam
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	awsclients "github.com/crossplane/provider-aws/pkg/clients"
+)
+
+// AnnotationKeyTrustPolicyCheck is the annotation that controls how
+// strictly ValidateTrustPolicy is enforced during reconciliation.
+const AnnotationKeyTrustPolicyCheck = "iam.aws.crossplane.io/trust-policy-check"
+
+// Trust policy check modes, set via AnnotationKeyTrustPolicyCheck.
+const (
+	TrustPolicyCheckStrict = "strict"
+	TrustPolicyCheckWarn   = "warn"
+	TrustPolicyCheckOff    = "off"
+)
+
+// assumeRoleActions are the actions considered to grant a trust
+// relationship when found on an Allow statement.
+var assumeRoleActions = map[string]struct{}{
+	"sts:AssumeRole":                {},
+	"sts:AssumeRoleWithSAML":        {},
+	"sts:AssumeRoleWithWebIdentity": {},
+}
+
+// restrictiveConditionKeys are the condition keys that, when present
+// alongside a wildcard principal, are considered sufficient to scope the
+// trust relationship down to something safe.
+var restrictiveConditionKeys = map[string]struct{}{
+	"aws:SourceAccount":  {},
+	"aws:SourceArn":      {},
+	"aws:PrincipalOrgID": {},
+	"sts:ExternalId":     {},
+}
+
+// restrictiveConditionOperators are the condition operators that narrow a
+// trust relationship down when paired with a restrictiveConditionKeys entry.
+// Negating operators such as StringNotEquals or ArnNotLike are deliberately
+// excluded: a condition like StringNotEquals on aws:SourceAccount *excludes*
+// one account rather than restricting the principal to it, so it must not be
+// treated as restrictive.
+var restrictiveConditionOperators = map[string]struct{}{
+	"StringEquals": {},
+	"ArnLike":      {},
+	"ArnEquals":    {},
+}
+
+// ErrOverlyPermissiveTrustPolicy is returned by ValidateTrustPolicy when an
+// AssumeRolePolicyDocument grants an assume-role action to an unrestricted
+// principal.
+type ErrOverlyPermissiveTrustPolicy struct {
+	Reason string
+}
+
+func (e *ErrOverlyPermissiveTrustPolicy) Error() string {
+	return fmt.Sprintf("trust policy is overly permissive: %s", e.Reason)
+}
+
+// TrustPolicyCheckMode returns the enforcement mode requested via
+// AnnotationKeyTrustPolicyCheck, defaulting to TrustPolicyCheckWarn when the
+// annotation is unset or holds an unrecognized value.
+func TrustPolicyCheckMode(annotations map[string]string) string {
+	switch annotations[AnnotationKeyTrustPolicyCheck] {
+	case TrustPolicyCheckStrict, TrustPolicyCheckOff:
+		return annotations[AnnotationKeyTrustPolicyCheck]
+	default:
+		return TrustPolicyCheckWarn
+	}
+}
+
+// ValidateTrustPolicy inspects an AssumeRolePolicyDocument and returns an
+// *ErrOverlyPermissiveTrustPolicy if an Allow statement grants an assume-role
+// action to "Principal": {"AWS": "*"}, "Principal": "*",
+// "Principal": {"Service": "*"} or a NotPrincipal (which grants to every
+// principal not listed), unless that statement also has a restrictive
+// Condition (StringEquals, ArnLike or ArnEquals on aws:SourceAccount,
+// aws:SourceArn, aws:PrincipalOrgID, or sts:ExternalId). NotAction is treated
+// as granting an assume-role action unless that action is itself one of the
+// excluded ones.
+func ValidateTrustPolicy(document string) error {
+	if _, err := awsclients.CompactAndEscapeJSON(document); err != nil {
+		return errors.Wrap(err, errPolicyJSONEscape)
+	}
+
+	doc, err := canonicalizePolicy(document)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range doc.Statement {
+		if !strings.EqualFold(s.Effect, "Allow") || !grantsAssumeRole(s) {
+			continue
+		}
+		if reason := wildcardPrincipalReason(s); reason != "" && !hasRestrictiveCondition(s.Condition) {
+			return &ErrOverlyPermissiveTrustPolicy{Reason: reason}
+		}
+	}
+	return nil
+}
+
+// grantsAssumeRole reports whether a statement grants an assume-role action,
+// whether that's spelled out in Action (including the sts:* and * wildcards)
+// or implied by NotAction: a NotAction list grants every action except the
+// ones it names, so it grants assume-role unless one of the assume-role
+// actions (or a wildcard covering them) is itself excluded.
+func grantsAssumeRole(s policyStatement) bool {
+	if len(s.Action) > 0 {
+		for _, a := range s.Action {
+			if a == "*" || a == "sts:*" {
+				return true
+			}
+			if _, ok := assumeRoleActions[a]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	for _, a := range s.NotAction {
+		if a == "*" || a == "sts:*" {
+			return false
+		}
+		if _, ok := assumeRoleActions[a]; ok {
+			return false
+		}
+	}
+	return len(s.NotAction) > 0
+}
+
+// wildcardPrincipalReason reports why a statement's principal is considered
+// unrestricted, or "" if it isn't. NotPrincipal is always unrestricted: on an
+// Allow statement it grants to every principal *except* the ones listed,
+// which is at least as permissive as an explicit wildcard.
+func wildcardPrincipalReason(s policyStatement) string {
+	if s.NotPrincipal != nil {
+		return "NotPrincipal grants to every principal not listed"
+	}
+	p := s.Principal
+	if p == nil {
+		return ""
+	}
+	if p.Wildcard {
+		return `Principal: "*"`
+	}
+	if containsWildcard(p.AWS) {
+		return `Principal: {"AWS": "*"}`
+	}
+	if containsWildcard(p.Service) {
+		return `Principal: {"Service": "*"}`
+	}
+	return ""
+}
+
+func containsWildcard(values stringSet) bool {
+	for _, v := range values {
+		if v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRestrictiveCondition(condition map[string]map[string]stringSet) bool {
+	for operator, keys := range condition {
+		if _, ok := restrictiveConditionOperators[operator]; !ok {
+			continue
+		}
+		for key := range keys {
+			if _, ok := restrictiveConditionKeys[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package iam
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateTrustPolicy(t *testing.T) {
+	cases := map[string]struct {
+		document string
+		wantErr  bool
+	}{
+		"ScopedToAccount": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":"arn:aws:iam::111122223333:root"}}]}`,
+		},
+		"ScopedServicePrincipal": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"ec2.amazonaws.com"}}]}`,
+		},
+		"WildcardAWSPrincipal": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":"*"}}]}`,
+			wantErr:  true,
+		},
+		"WildcardStringPrincipal": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":"*"}]}`,
+			wantErr:  true,
+		},
+		"WildcardServicePrincipal": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"*"}}]}`,
+			wantErr:  true,
+		},
+		"WildcardPrincipalRestrictedBySourceArn": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":"*"},"Condition":{"ArnLike":{"aws:SourceArn":"arn:aws:iam::111122223333:role/trusted"}}}]}`,
+		},
+		"WildcardPrincipalRestrictedByOrgID": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":"*"},"Condition":{"StringEquals":{"aws:PrincipalOrgID":"o-exampleorgid"}}}]}`,
+		},
+		"WildcardPrincipalWithNegatedConditionStillFails": {
+			// StringNotEquals on aws:SourceAccount excludes one account
+			// rather than restricting to it, so this grants every other
+			// account and must still be rejected.
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":"*"},"Condition":{"StringNotEquals":{"aws:SourceAccount":"999999999999"}}}]}`,
+			wantErr:  true,
+		},
+		"WildcardActionGrantsAssumeRole": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"*","Principal":{"AWS":"*"}}]}`,
+			wantErr:  true,
+		},
+		"WildcardSTSActionGrantsAssumeRole": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:*","Principal":{"AWS":"*"}}]}`,
+			wantErr:  true,
+		},
+		"DenyStatementIgnored": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"sts:AssumeRole","Principal":{"AWS":"*"}}]}`,
+		},
+		"NotActionGrantsAssumeRole": {
+			// NotAction grants every action except the ones listed, so a
+			// statement that excludes only an unrelated action still grants
+			// sts:AssumeRole to the wildcard principal.
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","NotAction":["iam:Whatever"],"Principal":{"AWS":"*"}}]}`,
+			wantErr:  true,
+		},
+		"NotActionExcludingAssumeRoleIsSafe": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","NotAction":["sts:AssumeRole","sts:AssumeRoleWithSAML","sts:AssumeRoleWithWebIdentity"],"Principal":{"AWS":"*"}}]}`,
+		},
+		"NotPrincipalGrantsToEveryoneElse": {
+			// NotPrincipal grants to every principal except the one listed,
+			// so this grants sts:AssumeRole to every account but one.
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","NotPrincipal":{"AWS":"111122223333"}}]}`,
+			wantErr:  true,
+		},
+		"NonAssumeRoleActionIgnored": {
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Principal":{"AWS":"*"}}]}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateTrustPolicy(tc.document)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateTrustPolicy(...): got err = %v, wantErr = %t", err, tc.wantErr)
+			}
+			if err != nil {
+				if _, ok := err.(*ErrOverlyPermissiveTrustPolicy); !ok {
+					t.Errorf("ValidateTrustPolicy(...): got error of type %T, want *ErrOverlyPermissiveTrustPolicy", err)
+				}
+			}
+		})
+	}
+}
+
+func TestTrustPolicyCheckMode(t *testing.T) {
+	cases := map[string]struct {
+		annotations map[string]string
+		want        string
+	}{
+		"Unset": {
+			annotations: nil,
+			want:        TrustPolicyCheckWarn,
+		},
+		"Strict": {
+			annotations: map[string]string{AnnotationKeyTrustPolicyCheck: "strict"},
+			want:        TrustPolicyCheckStrict,
+		},
+		"Off": {
+			annotations: map[string]string{AnnotationKeyTrustPolicyCheck: "off"},
+			want:        TrustPolicyCheckOff,
+		},
+		"Unrecognized": {
+			annotations: map[string]string{AnnotationKeyTrustPolicyCheck: "bogus"},
+			want:        TrustPolicyCheckWarn,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := TrustPolicyCheckMode(tc.annotations)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("TrustPolicyCheckMode(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}